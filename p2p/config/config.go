@@ -0,0 +1,56 @@
+// Package config contains the configuration structures used to construct the p2p swarm and its gossip protocol.
+package config
+
+import "time"
+
+// SwarmConfig is the configuration used by the p2p swarm and the gossip protocol built on top of it.
+type SwarmConfig struct {
+	// BroadcastFanoutFactor is the fraction (0,1] of the current peer set that a single gossip broadcast
+	// round needs to successfully deliver to before it is considered complete, e.g. 2.0/3.0. A zero or
+	// negative value falls back to sending to every peer, preserving the historical behavior.
+	BroadcastFanoutFactor float64
+
+	// PeerSendTimeout bounds how long a broadcast round waits on a single peer's SendMessage call before
+	// counting it as a failure. A zero value falls back to a built-in default.
+	PeerSendTimeout time.Duration
+
+	// PeerQueueSizeHigh, PeerQueueSizeMid and PeerQueueSizeLow set the depth of a peer's bounded outbound
+	// queue for each priorityq.Priority class. A zero value falls back to a built-in default.
+	PeerQueueSizeHigh int
+	PeerQueueSizeMid  int
+	PeerQueueSizeLow  int
+
+	// PeerFailureThreshold is the number of consecutive send errors or queue-full drops a peer may
+	// accumulate before it is marked unhealthy and evicted. A zero value falls back to a built-in default.
+	PeerFailureThreshold int
+
+	// Mode selects the default gossip dissemination mode; a zero value is ModePush, preserving the
+	// historical behavior. Individual protocols can override it via Protocol.SetMode.
+	Mode Mode
+
+	// PullFanout is the number of peers announced to in a single round of pull-mode dissemination. A zero
+	// value falls back to a built-in default.
+	PullFanout int
+
+	// HybridPushFanout is the number of peers ModeHybrid pushes the full payload to directly; the
+	// remaining known peers are covered by the pull (announce/get) path instead, and the two never overlap.
+	// A zero value falls back to a built-in default.
+	HybridPushFanout int
+
+	// PQWorkers is the number of concurrent workers draining the gossip priority queue. A zero value falls
+	// back to a built-in default. Raising it lets propagation of one large or slow message proceed
+	// alongside others instead of blocking the single consumer behind it.
+	PQWorkers int
+}
+
+// Mode selects how the gossip protocol disseminates a broadcast.
+type Mode int
+
+const (
+	// ModePush ships the full payload to every sampled peer (the historical behavior).
+	ModePush Mode = iota
+	// ModePull announces a message's hash first and only sends the payload to peers that ask for it.
+	ModePull
+	// ModeHybrid pushes to a subset of peers and pulls the rest, trading bandwidth for latency.
+	ModeHybrid
+)
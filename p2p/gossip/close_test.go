@@ -0,0 +1,78 @@
+package gossip
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/libonomy/libonomy-light/p2p/config"
+	"github.com/libonomy/libonomy-light/p2p/p2pcrypto"
+)
+
+// awaitGoroutineCount polls runtime.NumGoroutine until it drops to at most want, failing the test if it
+// never does within the deadline. Used to assert Close() doesn't leave goroutines behind.
+func awaitGoroutineCount(t *testing.T, want int, timeout time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		if n := runtime.NumGoroutine(); n <= want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutine count did not settle to <= %d within %s, have %d", want, timeout, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestClose_NoGoroutineLeak drives a broadcast round against a peer whose send never returns on its own,
+// then closes the protocol and asserts every goroutine the round spawned (the per-peer sender and the
+// wg.Wait completion watcher in propagateMessage) has exited rather than being left blocked forever.
+func TestClose_NoGoroutineLeak(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	net := &fakeNetwork{sendFunc: func(ctx context.Context, peer p2pcrypto.PublicKey, protocol string, payload []byte) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	prot := newTestProtocol(net, config.SwarmConfig{})
+	prot.addPeer(prot.ctx, p2pcrypto.NewRandomPubkey())
+
+	roundDone := make(chan struct{})
+	go func() {
+		prot.propagateMessage(prot.ctx, []byte("payload"), [12]byte{}, "proto", nil)
+		close(roundDone)
+	}()
+
+	// give propagateMessage a moment to actually enqueue and block on the peer's send before we close.
+	time.Sleep(50 * time.Millisecond)
+
+	prot.Close()
+
+	select {
+	case <-roundDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("propagateMessage did not return after Close")
+	}
+
+	awaitGoroutineCount(t, baseline, 2*time.Second)
+}
+
+// TestStart_NoGoroutineLeak drives the actual production entry/exit path - Start followed by Close -
+// rather than wiring eventLoop/propagationEventLoop up by hand, so it also covers the pqWorkers goroutines
+// propagationEventLoop spawns.
+func TestStart_NoGoroutineLeak(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	prot := newTestProtocol(&fakeNetwork{}, config.SwarmConfig{PQWorkers: 3})
+	prot.Start(context.Background())
+
+	// give eventLoop, propagationEventLoop and its pqWorkers goroutines a moment to actually start before
+	// closing.
+	time.Sleep(50 * time.Millisecond)
+
+	prot.Close()
+
+	awaitGoroutineCount(t, baseline, 2*time.Second)
+}
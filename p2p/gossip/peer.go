@@ -0,0 +1,208 @@
+package gossip
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/libonomy/libonomy-light/log"
+	"github.com/libonomy/libonomy-light/p2p/metrics"
+	"github.com/libonomy/libonomy-light/p2p/p2pcrypto"
+	"github.com/libonomy/libonomy-light/priorityq"
+)
+
+const (
+	defaultPeerQueueSize    = 64
+	defaultFailureThreshold = 5
+)
+
+// errQueueFull is returned by enqueue when the peer's outbound queue for the given priority is full.
+var errQueueFull = errors.New("peer outbound queue is full")
+
+// sender is an interface for peer's p2p layer
+type sender interface {
+	SendMessageWithContext(ctx context.Context, peerPubkey p2pcrypto.PublicKey, protocol string, payload []byte) error
+}
+
+// sendJob is a single outbound message queued for a peer's writer goroutine.
+type sendJob struct {
+	proto   string
+	payload []byte
+	result  chan<- error // optional, nil if the caller doesn't care about the outcome
+}
+
+// peer is a struct storing peer's state. Outbound gossip is never sent inline: it is enqueued onto one
+// of three bounded per-priority channels and delivered by a dedicated writer goroutine, so a single slow
+// peer can only ever block up to its own queue depth instead of spawning unbounded goroutines.
+type peer struct {
+	log.Log
+	pubkey p2pcrypto.PublicKey
+	net    sender
+
+	queues map[priorityq.Priority]chan sendJob
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	failureThreshold int
+	sendTimeout      time.Duration
+
+	mu                sync.Mutex
+	consecutiveErrors int
+	unhealthy         bool
+
+	// onUnhealthy, if set, is called once when the peer crosses failureThreshold so the owning Protocol
+	// can evict it.
+	onUnhealthy func(p2pcrypto.PublicKey)
+}
+
+// newPeer derives the peer's lifecycle context from ctx (typically the Protocol's own), so removePeer can
+// cancel just this peer's writer goroutine and in-flight sends without affecting the rest of the protocol.
+func newPeer(ctx context.Context, net sender, pubkey p2pcrypto.PublicKey, log log.Log, cfg peerQueueConfig, onUnhealthy func(p2pcrypto.PublicKey)) *peer {
+	peerCtx, cancel := context.WithCancel(ctx)
+	p := &peer{
+		Log:    log,
+		pubkey: pubkey,
+		net:    net,
+		queues: map[priorityq.Priority]chan sendJob{
+			priorityq.High: make(chan sendJob, orDefault(cfg.high, defaultPeerQueueSize)),
+			priorityq.Mid:  make(chan sendJob, orDefault(cfg.mid, defaultPeerQueueSize)),
+			priorityq.Low:  make(chan sendJob, orDefault(cfg.low, defaultPeerQueueSize)),
+		},
+		ctx:              peerCtx,
+		cancel:           cancel,
+		failureThreshold: orDefault(cfg.failureThreshold, defaultFailureThreshold),
+		sendTimeout:      orDefaultDuration(cfg.sendTimeout, defaultPeerSendTimeout),
+		onUnhealthy:      onUnhealthy,
+	}
+	go p.writeLoop()
+	return p
+}
+
+// peerQueueConfig carries the per-priority queue depths, failure threshold and send timeout down from
+// config.SwarmConfig.
+type peerQueueConfig struct {
+	high, mid, low   int
+	failureThreshold int
+	sendTimeout      time.Duration
+}
+
+func orDefault(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
+
+func orDefaultDuration(v, def time.Duration) time.Duration {
+	if v > 0 {
+		return v
+	}
+	return def
+}
+
+// enqueue schedules payload for delivery to this peer at priority, without blocking: if the matching
+// queue is full the message is dropped and errQueueFull is returned. result, if non-nil, receives the
+// outcome of the eventual SendMessage call.
+func (p *peer) enqueue(proto string, payload []byte, priority priorityq.Priority, result chan<- error) error {
+	q, ok := p.queues[priority]
+	if !ok {
+		q = p.queues[priorityq.Low]
+	}
+	select {
+	case q <- sendJob{proto: proto, payload: payload, result: result}:
+		metrics.PeerQueueLength.With(metrics.PeerLabel, p.pubkey.String()).Add(1)
+		return nil
+	default:
+		metrics.PeerQueueDrops.With(metrics.PeerLabel, p.pubkey.String()).Add(1)
+		p.recordFailure()
+		return errQueueFull
+	}
+}
+
+// writeLoop is the dedicated writer goroutine started by newPeer: it drains the peer's per-priority
+// queues, preferring higher priority messages, and performs the actual send. It exits once p.ctx is done,
+// i.e. when the peer is removed or the owning Protocol is closed.
+func (p *peer) writeLoop() {
+	high, mid, low := p.queues[priorityq.High], p.queues[priorityq.Mid], p.queues[priorityq.Low]
+	for {
+		select {
+		case job := <-high:
+			p.deliver(job)
+			continue
+		default:
+		}
+
+		select {
+		case job := <-mid:
+			p.deliver(job)
+			continue
+		default:
+		}
+
+		select {
+		case job := <-high:
+			p.deliver(job)
+		case job := <-mid:
+			p.deliver(job)
+		case job := <-low:
+			p.deliver(job)
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
+// deliver performs the actual send, bounding it with a deadline derived from p.ctx so a shutdown or peer
+// removal aborts an in-flight send instead of leaking the writer goroutine.
+func (p *peer) deliver(job sendJob) {
+	metrics.PeerQueueLength.With(metrics.PeerLabel, p.pubkey.String()).Add(-1)
+
+	ctx, cancel := context.WithTimeout(p.ctx, p.sendTimeout)
+	err := p.net.SendMessageWithContext(ctx, p.pubkey, job.proto, job.payload)
+	cancel()
+
+	if err != nil {
+		metrics.PeerSendErrors.With(metrics.PeerLabel, p.pubkey.String()).Add(1)
+		p.With().Warning("failed sending to peer", log.String("protocol", job.proto), log.String("to", p.pubkey.String()), log.Err(err))
+		p.recordFailure()
+	} else {
+		p.recordSuccess()
+	}
+	if job.result != nil {
+		job.result <- err
+	}
+}
+
+// recordFailure bumps the consecutive-failure count (shared by send errors and queue-full drops) and
+// marks the peer unhealthy once failureThreshold is crossed, notifying onUnhealthy exactly once.
+func (p *peer) recordFailure() {
+	p.mu.Lock()
+	p.consecutiveErrors++
+	becameUnhealthy := !p.unhealthy && p.consecutiveErrors >= p.failureThreshold
+	if becameUnhealthy {
+		p.unhealthy = true
+	}
+	p.mu.Unlock()
+
+	if becameUnhealthy {
+		p.With().Warning("peer exceeded failure threshold, evicting", log.String("peer", p.pubkey.String()))
+		if p.onUnhealthy != nil {
+			go p.onUnhealthy(p.pubkey)
+		}
+	}
+}
+
+func (p *peer) recordSuccess() {
+	p.mu.Lock()
+	p.consecutiveErrors = 0
+	p.mu.Unlock()
+}
+
+// isHealthy reports whether the peer is still under its failure threshold.
+func (p *peer) isHealthy() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !p.unhealthy
+}
@@ -0,0 +1,206 @@
+package gossip
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libonomy/libonomy-light/log"
+	"github.com/libonomy/libonomy-light/p2p/p2pcrypto"
+	"github.com/libonomy/libonomy-light/priorityq"
+)
+
+// fakeSender is a minimal sender used by the tests in this file: SendMessageWithContext defers to an
+// injectable sendFunc so a test can control exactly when and how a peer's writer goroutine's send
+// completes, without needing a real network.
+type fakeSender struct {
+	sendFunc func(ctx context.Context, protocol string) error
+}
+
+func (f *fakeSender) SendMessageWithContext(ctx context.Context, peerPubkey p2pcrypto.PublicKey, protocol string, payload []byte) error {
+	if f.sendFunc != nil {
+		return f.sendFunc(ctx, protocol)
+	}
+	return nil
+}
+
+func newTestPeer(net *fakeSender, cfg peerQueueConfig, onUnhealthy func(p2pcrypto.PublicKey)) *peer {
+	return newPeer(context.Background(), net, p2pcrypto.NewRandomPubkey(), log.NewDefault("peer-test"), cfg, onUnhealthy)
+}
+
+// TestEnqueue_DropsWhenQueueFull asserts that once a priority's queue is at capacity, enqueue reports
+// errQueueFull instead of blocking the caller.
+func TestEnqueue_DropsWhenQueueFull(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	net := &fakeSender{sendFunc: func(ctx context.Context, protocol string) error {
+		<-block
+		return nil
+	}}
+	p := newTestPeer(net, peerQueueConfig{low: 1}, nil)
+
+	// the first job is picked up by writeLoop immediately and blocks in deliver, occupying the worker so
+	// the queue itself is free to fill up behind it.
+	if err := p.enqueue("a", nil, priorityq.Low, nil); err != nil {
+		t.Fatalf("unexpected error on first enqueue: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if err := p.enqueue("b", nil, priorityq.Low, nil); err != nil {
+		t.Fatalf("unexpected error on second enqueue: %v", err)
+	}
+	if err := p.enqueue("c", nil, priorityq.Low, nil); !errors.Is(err, errQueueFull) {
+		t.Fatalf("expected third enqueue to be dropped as queue full, got %v", err)
+	}
+}
+
+// TestWriteLoop_PrefersHighPriority asserts that, given a choice, writeLoop drains high priority jobs
+// before mid, and mid before low.
+func TestWriteLoop_PrefersHighPriority(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	firstSendStarted := make(chan struct{})
+	release := make(chan struct{})
+
+	net := &fakeSender{sendFunc: func(ctx context.Context, protocol string) error {
+		mu.Lock()
+		first := len(order) == 0
+		order = append(order, protocol)
+		mu.Unlock()
+		if first {
+			close(firstSendStarted)
+			<-release // hold the worker so the jobs below pile up in their queues before writeLoop drains them
+		}
+		return nil
+	}}
+	p := newTestPeer(net, peerQueueConfig{}, nil)
+
+	if err := p.enqueue("blocker", nil, priorityq.Low, nil); err != nil {
+		t.Fatalf("unexpected error enqueueing blocker: %v", err)
+	}
+	<-firstSendStarted
+
+	for _, job := range []struct {
+		proto    string
+		priority priorityq.Priority
+	}{
+		{"low", priorityq.Low},
+		{"mid", priorityq.Mid},
+		{"high", priorityq.High},
+	} {
+		if err := p.enqueue(job.proto, nil, job.priority, nil); err != nil {
+			t.Fatalf("unexpected error enqueueing %s: %v", job.proto, err)
+		}
+	}
+
+	close(release)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := len(order)
+		mu.Unlock()
+		if n >= 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for all sends, got %v", order)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := order[1:]; got[0] != "high" || got[1] != "mid" || got[2] != "low" {
+		t.Fatalf("expected priority order [high mid low] after the blocker, got %v", got)
+	}
+}
+
+// TestPeer_EvictsAfterFailureThreshold asserts that onUnhealthy fires exactly once, the send after
+// consecutiveErrors crosses failureThreshold, even if further sends keep failing afterward.
+func TestPeer_EvictsAfterFailureThreshold(t *testing.T) {
+	net := &fakeSender{sendFunc: func(ctx context.Context, protocol string) error {
+		return errors.New("boom")
+	}}
+
+	var mu sync.Mutex
+	var evictions int
+	onUnhealthy := func(p2pcrypto.PublicKey) {
+		mu.Lock()
+		evictions++
+		mu.Unlock()
+	}
+	p := newTestPeer(net, peerQueueConfig{failureThreshold: 2}, onUnhealthy)
+
+	for i := 0; i < 4; i++ {
+		resultc := make(chan error, 1)
+		if err := p.enqueue("proto", nil, priorityq.Low, resultc); err != nil {
+			t.Fatalf("enqueue %d: %v", i, err)
+		}
+		<-resultc
+	}
+
+	// recordFailure invokes onUnhealthy in its own goroutine; give it a moment to run.
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if evictions != 1 {
+		t.Fatalf("expected exactly one eviction callback, got %d", evictions)
+	}
+	if p.isHealthy() {
+		t.Fatal("expected peer to be marked unhealthy after crossing the failure threshold")
+	}
+}
+
+// TestRecordSuccess_ResetsConsecutiveErrors asserts that a successful send clears the failure streak, so
+// an intermittently failing peer isn't evicted by failures that aren't actually consecutive.
+func TestRecordSuccess_ResetsConsecutiveErrors(t *testing.T) {
+	var mu sync.Mutex
+	var fail bool
+	net := &fakeSender{sendFunc: func(ctx context.Context, protocol string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	}}
+
+	var evictions int
+	onUnhealthy := func(p2pcrypto.PublicKey) {
+		mu.Lock()
+		evictions++
+		mu.Unlock()
+	}
+	p := newTestPeer(net, peerQueueConfig{failureThreshold: 2}, onUnhealthy)
+
+	send := func(shouldFail bool) {
+		mu.Lock()
+		fail = shouldFail
+		mu.Unlock()
+		resultc := make(chan error, 1)
+		if err := p.enqueue("proto", nil, priorityq.Low, resultc); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+		<-resultc
+	}
+
+	send(true)
+	send(false) // resets consecutiveErrors to 0
+	send(true)
+
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if evictions != 0 {
+		t.Fatalf("expected no eviction, consecutive failures never reached the threshold: got %d", evictions)
+	}
+	if !p.isHealthy() {
+		t.Fatal("expected peer to still be healthy")
+	}
+}
@@ -2,7 +2,11 @@
 package gossip
 
 import (
+	"context"
+	"math"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/libonomy/libonomy-light/common/types"
 	"github.com/libonomy/libonomy-light/common/util"
@@ -17,13 +21,56 @@ import (
 const oldMessageCacheSize = 10000
 const propagateHandleBufferSize = 5000 // number of MessageValidation that we allow buffering, above this number protocols will get stuck
 
+// defaultFanoutFactor is used when neither a per-protocol override nor config.SwarmConfig.BroadcastFanoutFactor
+// is set: wait for every peer, matching the historical behavior.
+const defaultFanoutFactor = 1.0
+
+// defaultPeerSendTimeout bounds a single peer's send when config.SwarmConfig.PeerSendTimeout isn't set.
+const defaultPeerSendTimeout = 2 * time.Second
+
+// defaultHybridPushFanout is used when config.SwarmConfig.HybridPushFanout isn't set.
+const defaultHybridPushFanout = 6
+
 // Interface for the underlying p2p layer
 type baseNetwork interface {
 	SendMessage(peerPubkey p2pcrypto.PublicKey, protocol string, payload []byte) error
+	// SendMessageWithContext behaves like SendMessage but aborts once ctx is done, so a protocol shutdown
+	// can cleanly unblock a send instead of leaking a goroutine waiting on the network.
+	SendMessageWithContext(ctx context.Context, peerPubkey p2pcrypto.PublicKey, protocol string, payload []byte) error
 	SubscribePeerEvents() (conn chan p2pcrypto.PublicKey, disc chan p2pcrypto.PublicKey)
 	ProcessGossipProtocolMessage(sender p2pcrypto.PublicKey, protocol string, data service.Data, validationCompletedChan chan service.MessageValidation) error
 }
 
+// legacyBaseNetwork is the pre-context network surface that existing baseNetwork implementations satisfy.
+type legacyBaseNetwork interface {
+	SendMessage(peerPubkey p2pcrypto.PublicKey, protocol string, payload []byte) error
+	SubscribePeerEvents() (conn chan p2pcrypto.PublicKey, disc chan p2pcrypto.PublicKey)
+	ProcessGossipProtocolMessage(sender p2pcrypto.PublicKey, protocol string, data service.Data, validationCompletedChan chan service.MessageValidation) error
+}
+
+// legacyBaseNetworkShim adapts a legacyBaseNetwork to baseNetwork by implementing SendMessageWithContext
+// on top of the plain SendMessage: the underlying call can't itself be interrupted, but the shim stops
+// waiting on it once ctx is done so callers aren't blocked by a shutdown.
+type legacyBaseNetworkShim struct {
+	legacyBaseNetwork
+}
+
+// WrapLegacyNetwork adapts a pre-context baseNetwork implementation so it can be passed to NewProtocol.
+func WrapLegacyNetwork(base legacyBaseNetwork) baseNetwork {
+	return legacyBaseNetworkShim{base}
+}
+
+func (l legacyBaseNetworkShim) SendMessageWithContext(ctx context.Context, peerPubkey p2pcrypto.PublicKey, protocol string, payload []byte) error {
+	errc := make(chan error, 1)
+	go func() { errc <- l.SendMessage(peerPubkey, protocol, payload) }()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 type prioQ interface {
 	Write(prio priorityq.Priority, m interface{}) error
 	Read() (interface{}, error)
@@ -41,91 +88,244 @@ type Protocol struct {
 	peers      map[p2pcrypto.PublicKey]*peer
 	peersMutex sync.RWMutex
 
-	shutdown chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
 
 	oldMessageQ *types.DoubleCache
 
 	propagateQ chan service.MessageValidation
 	pq         prioQ
 	priorities map[string]priorityq.Priority
+
+	fanoutMutex sync.RWMutex
+	fanout      map[string]float64
+
+	sampler PeerSampler
+
+	modesMutex sync.RWMutex
+	modes      map[string]config.Mode
+
+	pendingMutex sync.Mutex
+	pending      map[types.Hash12]*pendingGet
+	// pullAnnounceQ dedupes incoming pull-mode announces so this node only sends one get per hash, even if
+	// several peers announce it before a response arrives.
+	pullAnnounceQ *types.DoubleCache
+
+	rateLimitMutex sync.RWMutex
+	rateLimiters   map[string]*tokenBucket
+}
+
+// pqItem wraps a MessageValidation with the time it was written to the priority queue, so a handlePQ
+// worker can report how long it waited before being picked up.
+type pqItem struct {
+	msg        service.MessageValidation
+	enqueuedAt time.Time
 }
 
 // NewProtocol creates a new gossip protocol instance.
 func NewProtocol(config config.SwarmConfig, base baseNetwork, localNodePubkey p2pcrypto.PublicKey, log2 log.Log) *Protocol {
 	// intentionally not subscribing to peers events so that the channels won't block in case executing Start delays
-	return &Protocol{
+	// ctx/cancel are placeholders until Start(ctx) derives the real lifecycle context; this only matters
+	// if Close is called before Start, which is otherwise a no-op.
+	ctx, cancel := context.WithCancel(context.Background())
+	prot := &Protocol{
 		Log:             log2,
 		config:          config,
 		net:             base,
 		localNodePubkey: localNodePubkey,
 		peers:           make(map[p2pcrypto.PublicKey]*peer),
-		shutdown:        make(chan struct{}),
+		ctx:             ctx,
+		cancel:          cancel,
 		oldMessageQ:     types.NewDoubleCache(oldMessageCacheSize), // todo : remember to drain this
 		propagateQ:      make(chan service.MessageValidation, propagateHandleBufferSize),
 		pq:              priorityq.New(propagateHandleBufferSize),
 		priorities:      make(map[string]priorityq.Priority),
+		fanout:          make(map[string]float64),
+		modes:           newModeMap(),
+		pending:         make(map[types.Hash12]*pendingGet),
+		pullAnnounceQ:   types.NewDoubleCache(pullAnnounceCacheSize),
+		rateLimiters:    make(map[string]*tokenBucket),
 	}
+	prot.sampler = uniformSampler{prot: prot}
+	return prot
 }
 
-// sender is an interface for peer's p2p layer
-type sender interface {
-	SendMessage(peerPubkey p2pcrypto.PublicKey, protocol string, payload []byte) error
+// Close stops all protocol routines by canceling the protocol's lifecycle context.
+func (prot *Protocol) Close() {
+	prot.cancel()
 }
 
-// peer is a struct storing peer's state
-type peer struct {
-	log.Log
-	pubkey p2pcrypto.PublicKey
-	net    sender
+// markMessageAsOld adds the message's hash to the old messages queue so that the message won't be processed in case received again.
+// Returns true if message was already processed before
+func (prot *Protocol) markMessageAsOld(h types.Hash12) bool {
+	ok := prot.oldMessageQ.GetOrInsert(h)
+	return ok
 }
 
-func newPeer(net sender, pubkey p2pcrypto.PublicKey, log log.Log) *peer {
-	return &peer{
-		log,
-		pubkey,
-		net,
+// getFanout returns the delivery-ratio target for protoName: a per-protocol override set via SetFanout takes
+// precedence, then config.SwarmConfig.BroadcastFanoutFactor, falling back to defaultFanoutFactor (send to all).
+func (prot *Protocol) getFanout(protoName string) float64 {
+	prot.fanoutMutex.RLock()
+	ratio, exist := prot.fanout[protoName]
+	prot.fanoutMutex.RUnlock()
+	if exist {
+		return ratio
+	}
+	if prot.config.BroadcastFanoutFactor > 0 {
+		return prot.config.BroadcastFanoutFactor
 	}
+	return defaultFanoutFactor
 }
 
-// Close stops all protocol routines.
-func (prot *Protocol) Close() {
-	close(prot.shutdown)
+// SetFanout sets the delivery-ratio target used when broadcasting on protoName, overriding
+// config.SwarmConfig.BroadcastFanoutFactor for that protocol. A ratio of 1 waits on every peer (e.g.
+// consensus-critical protocols); lower ratios (e.g. 2.0/3.0) let propagateMessage return once that
+// fraction of peers has accepted the payload, instead of waiting on the slowest stragglers.
+func (prot *Protocol) SetFanout(protoName string, ratio float64) {
+	prot.fanoutMutex.Lock()
+	prot.fanout[protoName] = ratio
+	prot.fanoutMutex.Unlock()
 }
 
-// markMessageAsOld adds the message's hash to the old messages queue so that the message won't be processed in case received again.
-// Returns true if message was already processed before
-func (prot *Protocol) markMessageAsOld(h types.Hash12) bool {
-	ok := prot.oldMessageQ.GetOrInsert(h)
-	return ok
+// SetPeerSampler overrides the PeerSampler used to select peers for push fanout and pull announces,
+// taking precedence over the uniformSampler installed by NewProtocol. Since PeerSampler.SelectPeers deals
+// in p2pcrypto.PublicKey rather than Protocol's unexported peer type, a custom sampler can be implemented
+// entirely outside this package.
+func (prot *Protocol) SetPeerSampler(sampler PeerSampler) {
+	prot.sampler = sampler
 }
 
-// send a message to all the peers.
-func (prot *Protocol) propagateMessage(payload []byte, h types.Hash12, nextProt string, exclude p2pcrypto.PublicKey) {
-	//TODO soon : don't wait for mesaage to send and if we finished sending last message one of the peers send the next message to him.
-	// limit the number of simultaneous sends. *consider other messages (mainly sync)
+// hybridPushFanout returns the number of peers propagateMessage pushes the full payload to directly under
+// config.ModeHybrid; the rest are left to broadcastPull.
+func (prot *Protocol) hybridPushFanout() int {
+	if prot.config.HybridPushFanout > 0 {
+		return prot.config.HybridPushFanout
+	}
+	return defaultHybridPushFanout
+}
+
+// resolvePeers maps pubkeys, as returned by a PeerSampler, back to this protocol's known *peer objects,
+// silently dropping any that disconnected between being sampled and resolved.
+func (prot *Protocol) resolvePeers(pubkeys []p2pcrypto.PublicKey) []*peer {
 	prot.peersMutex.RLock()
-	peers := make([]p2pcrypto.PublicKey, 0, len(prot.peers))
-	for p := range prot.peers {
-		peers = append(peers, p)
+	defer prot.peersMutex.RUnlock()
+	peers := make([]*peer, 0, len(pubkeys))
+	for _, k := range pubkeys {
+		if p, ok := prot.peers[k]; ok {
+			peers = append(peers, p)
+		}
 	}
-	prot.peersMutex.RUnlock()
-	var wg sync.WaitGroup
-peerLoop:
+	return peers
+}
+
+// sendTimeout returns the per-peer send deadline used by propagateMessage.
+func (prot *Protocol) sendTimeout() time.Duration {
+	if prot.config.PeerSendTimeout > 0 {
+		return prot.config.PeerSendTimeout
+	}
+	return defaultPeerSendTimeout
+}
+
+// send a message to the shuffled peer set (every sampled peer but exclude; under ModeHybrid only the first
+// hybridPushFanout of them), returning once the protocol's fanout ratio of them has accepted the payload
+// rather than waiting on every peer. Slow or blocked peers are timed out via a per-send deadline derived
+// from the peer's own context (enforced inside peer.deliver) and counted as failures, so one stuck peer
+// cannot stall the round, and canceling ctx aborts the round immediately.
+func (prot *Protocol) propagateMessage(ctx context.Context, payload []byte, h types.Hash12, nextProt string, exclude p2pcrypto.PublicKey) {
+	mode := prot.getMode(nextProt)
+	if mode == config.ModePull {
+		prot.broadcastPull(ctx, payload, h, nextProt, exclude)
+		return
+	}
+
+	peers := prot.resolvePeers(prot.sampler.SelectPeers(0))
+	filtered := make([]*peer, 0, len(peers))
 	for _, p := range peers {
-		if exclude == p {
-			continue peerLoop
+		if p.pubkey != exclude {
+			filtered = append(filtered, p)
+		}
+	}
+	peers = filtered
+
+	if mode == config.ModeHybrid {
+		// Partition the (already shuffled) peer set once: a fixed-size subset is pushed the full payload
+		// below, while the remainder is left to broadcastPull, seeded to skip that subset so the two paths
+		// never double-send to the same peer.
+		n := prot.hybridPushFanout()
+		if n > len(peers) {
+			n = len(peers)
+		}
+		peers = peers[:n]
+
+		pullSkip := make([]p2pcrypto.PublicKey, 0, len(peers))
+		for _, p := range peers {
+			pullSkip = append(pullSkip, p.pubkey)
 		}
+		go prot.broadcastPull(ctx, payload, h, nextProt, exclude, pullSkip...)
+	}
+
+	target := int(math.Ceil(prot.getFanout(nextProt) * float64(len(peers))))
+	if target > len(peers) {
+		target = len(peers)
+	}
+
+	start := time.Now()
+	if len(peers) > 0 {
+		metrics.BroadcastPeersAttempted.With(metrics.ProtocolLabel, nextProt).Add(float64(len(peers)))
+	}
+
+	done := make(chan struct{})
+	var closeOnce sync.Once
+	signalDone := func() { closeOnce.Do(func() { close(done) }) }
+	if target == 0 {
+		signalDone()
+	}
+
+	priority := prot.getPriority(nextProt)
+	var delivered int32
+	var wg sync.WaitGroup
+	for _, p := range peers {
 		wg.Add(1)
-		go func(pubkey p2pcrypto.PublicKey) {
-			// TODO: replace peer ?
-			err := prot.net.SendMessage(pubkey, nextProt, payload)
-			if err != nil {
-				prot.With().Warning("Failed sending", log.String("protocol", nextProt), h.Field("hash"), log.String("to", pubkey.String()), log.Err(err))
+		go func(p *peer) {
+			defer wg.Done()
+			resultc := make(chan error, 1)
+			if err := p.enqueue(nextProt, payload, priority, resultc); err != nil {
+				prot.With().Warning("could not enqueue to peer", log.String("protocol", nextProt), h.Field("hash"), log.String("to", p.pubkey.String()), log.Err(err))
+				return
+			}
+
+			select {
+			case err := <-resultc:
+				if err != nil {
+					return
+				}
+				metrics.BroadcastPeersDelivered.With(metrics.ProtocolLabel, nextProt).Add(1)
+				if int(atomic.AddInt32(&delivered, 1)) >= target {
+					signalDone()
+				}
+			case <-ctx.Done():
+			case <-p.ctx.Done():
+				// p was removed (its own ctx, not the round's, was canceled) after enqueue already
+				// succeeded: its writeLoop has exited and will never drain resultc, so waiting on the
+				// round's ctx alone would block this goroutine until Close(), not until the round ends.
 			}
-			wg.Done()
 		}(p)
 	}
-	wg.Wait()
+
+	// every goroutine above returns once it either delivers, fails, or ctx is canceled, so "all of them
+	// finished" is itself a valid completion signal: in normal p2p operation not every peer succeeds, so
+	// gating solely on the delivered-count reaching target would block here forever the first time a
+	// single send fails or times out.
+	go func() {
+		wg.Wait()
+		signalDone()
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	metrics.BroadcastRoundLatency.With(metrics.ProtocolLabel, nextProt).Set(time.Since(start).Seconds())
 }
 
 // Broadcast is the actual broadcast procedure - process the message internally and loop on peers and add the message to their queues
@@ -135,25 +335,57 @@ func (prot *Protocol) Broadcast(payload []byte, nextProt string) error {
 	//todo: should this ever return error ? then when processMessage should return error ?. should it block?
 }
 
-// Start a loop that process peers events
-func (prot *Protocol) Start() {
+// Start a loop that process peers events. ctx governs the protocol's entire lifecycle: canceling it (or
+// calling Close, which cancels the context derived from it here) stops every goroutine the protocol owns.
+func (prot *Protocol) Start(ctx context.Context) {
+	prot.ctx, prot.cancel = context.WithCancel(ctx)
 	peerConn, peerDisc := prot.net.SubscribePeerEvents() // this was start blocks until we registered.
-	go prot.eventLoop(peerConn, peerDisc)
-	go prot.propagationEventLoop() // TODO consider running several consumers
+	go prot.eventLoop(prot.ctx, peerConn, peerDisc)
+	go prot.propagationEventLoop(prot.ctx)
+}
+
+// peerQueueConfig builds the per-priority queue depths and failure threshold passed to newPeer from
+// config.SwarmConfig, letting each field fall back to a built-in default independently.
+func (prot *Protocol) peerQueueConfig() peerQueueConfig {
+	return peerQueueConfig{
+		high:             prot.config.PeerQueueSizeHigh,
+		mid:              prot.config.PeerQueueSizeMid,
+		low:              prot.config.PeerQueueSizeLow,
+		failureThreshold: prot.config.PeerFailureThreshold,
+		sendTimeout:      prot.sendTimeout(),
+	}
 }
 
-func (prot *Protocol) addPeer(peer p2pcrypto.PublicKey) {
+// addPeer derives a child of ctx for the new peer's writer goroutine, so removePeer can cancel just that
+// peer's sends without tearing down the rest of the protocol.
+func (prot *Protocol) addPeer(ctx context.Context, pubkey p2pcrypto.PublicKey) {
 	prot.peersMutex.Lock()
-	prot.peers[peer] = newPeer(prot.net, peer, prot.Log)
-	prot.Log.With().Info("adding peer", log.String("peer", peer.String()))
+	prot.peers[pubkey] = newPeer(ctx, prot.net, pubkey, prot.Log, prot.peerQueueConfig(), prot.evictUnhealthyPeer)
+	prot.Log.With().Info("adding peer", log.String("peer", pubkey.String()))
 	prot.peersMutex.Unlock()
 }
 
-func (prot *Protocol) removePeer(peer p2pcrypto.PublicKey) {
+func (prot *Protocol) removePeer(pubkey p2pcrypto.PublicKey) {
 	prot.peersMutex.Lock()
-	delete(prot.peers, peer)
-	prot.Log.With().Info("deleting peer", log.String("peer", peer.String()))
+	if p, exist := prot.peers[pubkey]; exist {
+		p.cancel()
+	}
+	delete(prot.peers, pubkey)
+	prot.Log.With().Info("deleting peer", log.String("peer", pubkey.String()))
 	prot.peersMutex.Unlock()
+
+	// the peer is gone for good (pubkeys aren't reused across reconnects), so its per-peer metric series
+	// would otherwise accumulate forever under normal peer churn.
+	key := pubkey.String()
+	metrics.PeerQueueLength.DeleteLabelValues(key)
+	metrics.PeerQueueDrops.DeleteLabelValues(key)
+	metrics.PeerSendErrors.DeleteLabelValues(key)
+}
+
+// evictUnhealthyPeer is invoked, in its own goroutine, once a peer crosses its failure threshold, so
+// the upper layer's disconnect flow gets the same treatment as a regular peer disconnect event.
+func (prot *Protocol) evictUnhealthyPeer(pubkey p2pcrypto.PublicKey) {
+	prot.removePeer(pubkey)
 }
 
 func (prot *Protocol) processMessage(sender p2pcrypto.PublicKey, protocol string, msg service.Data) error {
@@ -174,21 +406,43 @@ func (prot *Protocol) processMessage(sender p2pcrypto.PublicKey, protocol string
 	return prot.net.ProcessGossipProtocolMessage(sender, protocol, msg, prot.propagateQ)
 }
 
-func (prot *Protocol) handlePQ() {
+// handlePQ is one of several concurrent workers draining prot.pq (see propagationEventLoop). Running more
+// than one means a large or slow propagation no longer blocks every other message queued behind it,
+// regardless of priority.
+func (prot *Protocol) handlePQ(ctx context.Context) {
 	for {
-		mi, err := prot.pq.Read()
+		qi, err := prot.pq.Read()
 		if err != nil {
 			prot.With().Info("priority queue was closed, existing", log.Err(err))
 			return
 		}
-		m, ok := mi.(service.MessageValidation)
+		item, ok := qi.(pqItem)
 		if !ok {
-			prot.Error("could not convert to message validation, ignoring message")
+			prot.Error("could not convert to priority queue item, ignoring message")
 			continue
 		}
-		h := types.CalcMessageHash12(m.Message(), m.Protocol())
-		prot.Log.With().Debug("new_gossip_message_relay", log.String("protocol", m.Protocol()), log.String("hash", util.Bytes2Hex(h[:])))
-		prot.propagateMessage(m.Message(), h, m.Protocol(), m.Sender())
+		m := item.msg
+		protoName := m.Protocol()
+
+		metrics.PropagationQueueWait.With(metrics.ProtocolLabel, protoName).Set(time.Since(item.enqueuedAt).Seconds())
+
+		// A protocol over its rate limit is deferred, not dropped: the intent of SetRateLimit is to keep
+		// a high-volume protocol from starving others out of the shared pq workers, not to lose its
+		// messages. Requeuing it after a short delay, rather than blocking this worker until its bucket
+		// refills, matters once a burst of same-protocol messages lands: blocking in place lets every
+		// worker pile up on the same exhausted bucket, starving every other protocol exactly as this
+		// mechanism is meant to prevent.
+		if !prot.allowPropagation(protoName) {
+			metrics.RateLimitDeferrals.With(metrics.ProtocolLabel, protoName).Add(1)
+			prot.With().Debug("rate limit exceeded, requeueing message", log.String("protocol", protoName))
+			prot.requeueAfterRateLimit(ctx, item)
+			continue
+		}
+
+		h := types.CalcMessageHash12(m.Message(), protoName)
+		prot.Log.With().Debug("new_gossip_message_relay", log.String("protocol", protoName), log.String("hash", util.Bytes2Hex(h[:])))
+		prot.propagateMessage(ctx, m.Message(), h, protoName, m.Sender())
+		metrics.PropagationThroughput.With(metrics.ProtocolLabel, protoName).Add(1)
 	}
 }
 
@@ -202,19 +456,22 @@ func (prot *Protocol) getPriority(protoName string) priorityq.Priority {
 	return v
 }
 
-// pushes messages that passed validation into the priority queue
-func (prot *Protocol) propagationEventLoop() {
-	go prot.handlePQ()
+// pushes messages that passed validation into the priority queue and runs the workers that drain it.
+func (prot *Protocol) propagationEventLoop(ctx context.Context) {
+	for i := 0; i < prot.pqWorkers(); i++ {
+		go prot.handlePQ(ctx)
+	}
 
 	for {
 		select {
 		case msgV := <-prot.propagateQ:
-			if err := prot.pq.Write(prot.getPriority(msgV.Protocol()), msgV); err != nil {
+			item := pqItem{msg: msgV, enqueuedAt: time.Now()}
+			if err := prot.pq.Write(prot.getPriority(msgV.Protocol()), item); err != nil {
 				prot.With().Error("fatal: could not write to priority queue", log.Err(err), log.String("protocol", msgV.Protocol()))
 			}
 			metrics.PropagationQueueLen.Set(float64(len(prot.propagateQ)))
 
-		case <-prot.shutdown:
+		case <-ctx.Done():
 			prot.pq.Close()
 			prot.Error("propagate event loop stopped: protocol shutdown")
 			return
@@ -223,11 +480,17 @@ func (prot *Protocol) propagationEventLoop() {
 }
 
 // Relay processes a message, if the message is new, it is passed for the protocol to validate and then propagated.
+// Pull-mode control frames - announce and get - are routed to their own handlers instead: the network
+// layer delivers them under protocol+announceSuffix/getSuffix, which dispatchPullFrame recognizes and
+// strips back to the original protocol name before handing off.
 func (prot *Protocol) Relay(sender p2pcrypto.PublicKey, protocol string, msg service.Data) error {
+	if handled, err := prot.dispatchPullFrame(sender, protocol, msg); handled {
+		return err
+	}
 	return prot.processMessage(sender, protocol, msg)
 }
 
-func (prot *Protocol) eventLoop(peerConn, peerDisc chan p2pcrypto.PublicKey) {
+func (prot *Protocol) eventLoop(ctx context.Context, peerConn, peerDisc chan p2pcrypto.PublicKey) {
 	// TODO: replace with p2p.Peers
 	defer prot.Info("Gossip protocol shutdown")
 	for {
@@ -236,13 +499,13 @@ func (prot *Protocol) eventLoop(peerConn, peerDisc chan p2pcrypto.PublicKey) {
 			if !ok {
 				return
 			}
-			go prot.addPeer(peer)
+			go prot.addPeer(ctx, peer)
 		case peer, ok := <-peerDisc:
 			if !ok {
 				return
 			}
 			go prot.removePeer(peer)
-		case <-prot.shutdown:
+		case <-ctx.Done():
 			return
 		}
 	}
@@ -0,0 +1,184 @@
+package gossip
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libonomy/libonomy-light/log"
+	"github.com/libonomy/libonomy-light/p2p/config"
+	"github.com/libonomy/libonomy-light/p2p/p2pcrypto"
+	"github.com/libonomy/libonomy-light/p2p/service"
+)
+
+// fakeNetwork is a minimal baseNetwork used by the tests in this package: SendMessageWithContext
+// defers to an injectable sendFunc so a test can simulate a specific peer failing, timing out, or
+// blocking until ctx is canceled, without needing a real network.
+type fakeNetwork struct {
+	sendFunc func(ctx context.Context, peer p2pcrypto.PublicKey, protocol string, payload []byte) error
+}
+
+func (f *fakeNetwork) SendMessage(peer p2pcrypto.PublicKey, protocol string, payload []byte) error {
+	return f.SendMessageWithContext(context.Background(), peer, protocol, payload)
+}
+
+func (f *fakeNetwork) SendMessageWithContext(ctx context.Context, peer p2pcrypto.PublicKey, protocol string, payload []byte) error {
+	if f.sendFunc != nil {
+		return f.sendFunc(ctx, peer, protocol, payload)
+	}
+	return nil
+}
+
+func (f *fakeNetwork) SubscribePeerEvents() (conn chan p2pcrypto.PublicKey, disc chan p2pcrypto.PublicKey) {
+	return make(chan p2pcrypto.PublicKey), make(chan p2pcrypto.PublicKey)
+}
+
+func (f *fakeNetwork) ProcessGossipProtocolMessage(sender p2pcrypto.PublicKey, protocol string, data service.Data, validationCompletedChan chan service.MessageValidation) error {
+	return nil
+}
+
+func newTestProtocol(net *fakeNetwork, cfg config.SwarmConfig) *Protocol {
+	return NewProtocol(cfg, net, p2pcrypto.NewRandomPubkey(), log.NewDefault("gossip-test"))
+}
+
+// TestGetFanout_PerProtocolOverride asserts SetFanout takes precedence over config.BroadcastFanoutFactor,
+// which in turn takes precedence over the default of waiting for every peer.
+func TestGetFanout_PerProtocolOverride(t *testing.T) {
+	prot := newTestProtocol(&fakeNetwork{}, config.SwarmConfig{})
+
+	if got := prot.getFanout("proto"); got != defaultFanoutFactor {
+		t.Fatalf("expected default fanout %v, got %v", defaultFanoutFactor, got)
+	}
+
+	prot.config.BroadcastFanoutFactor = 0.5
+	if got := prot.getFanout("proto"); got != 0.5 {
+		t.Fatalf("expected config fanout 0.5, got %v", got)
+	}
+
+	prot.SetFanout("proto", 0.75)
+	if got := prot.getFanout("proto"); got != 0.75 {
+		t.Fatalf("expected per-protocol override 0.75, got %v", got)
+	}
+	if got := prot.getFanout("other-proto"); got != 0.5 {
+		t.Fatalf("override on one protocol leaked into another: got %v", got)
+	}
+}
+
+// TestPropagateMessage_CompletesWhenTargetIsUnreachable is the regression test for the reported deadlock:
+// with the default fanout (wait for every peer) a single failing peer used to mean delivered never
+// reached target, so propagateMessage's completion wait blocked forever. It must instead return once
+// every per-peer goroutine has finished, successful or not.
+func TestPropagateMessage_CompletesWhenTargetIsUnreachable(t *testing.T) {
+	okPeer := p2pcrypto.NewRandomPubkey()
+	failPeer := p2pcrypto.NewRandomPubkey()
+
+	net := &fakeNetwork{sendFunc: func(ctx context.Context, peer p2pcrypto.PublicKey, protocol string, payload []byte) error {
+		if peer == failPeer {
+			return errQueueFull
+		}
+		return nil
+	}}
+	prot := newTestProtocol(net, config.SwarmConfig{})
+	prot.addPeer(prot.ctx, okPeer)
+	prot.addPeer(prot.ctx, failPeer)
+
+	done := make(chan struct{})
+	go func() {
+		prot.propagateMessage(prot.ctx, []byte("payload"), [12]byte{}, "proto", nil)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("propagateMessage did not return after a peer failed; it deadlocked waiting for the unreachable fanout target")
+	}
+}
+
+// TestPropagateMessage_ReturnsOnceFanoutTargetIsMet is the completion-condition test for the subset-fanout
+// behavior: with 4 peers and a 0.5 fanout ratio, propagateMessage must return as soon as the 2 peers that
+// respond instantly push delivered past target, without waiting on the other 2, which block until the
+// round's ctx is canceled (well past the peer send-timeout if it ever came to that).
+func TestPropagateMessage_ReturnsOnceFanoutTargetIsMet(t *testing.T) {
+	// built once before propagateMessage runs and never written to again, so concurrent reads from sendFunc
+	// need no locking.
+	fastPeers := map[p2pcrypto.PublicKey]struct{}{}
+	for i := 0; i < 2; i++ {
+		fastPeers[p2pcrypto.NewRandomPubkey()] = struct{}{}
+	}
+
+	net := &fakeNetwork{sendFunc: func(ctx context.Context, peer p2pcrypto.PublicKey, protocol string, payload []byte) error {
+		if _, fast := fastPeers[peer]; fast {
+			return nil
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	prot := newTestProtocol(net, config.SwarmConfig{BroadcastFanoutFactor: 0.5, PeerSendTimeout: time.Hour})
+	defer prot.Close() // unblocks the two slow peers' sends instead of leaving them stuck for PeerSendTimeout
+	for pk := range fastPeers {
+		prot.addPeer(prot.ctx, pk)
+	}
+	prot.addPeer(prot.ctx, p2pcrypto.NewRandomPubkey())
+	prot.addPeer(prot.ctx, p2pcrypto.NewRandomPubkey())
+
+	start := time.Now()
+	done := make(chan struct{})
+	go func() {
+		prot.propagateMessage(prot.ctx, []byte("payload"), [12]byte{}, "proto", nil)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("propagateMessage did not return once the fanout target was met")
+	}
+
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Fatalf("propagateMessage took %s; expected it to return soon after the fast peers delivered instead of waiting on the blocked ones (send timeout was set to 1h)", elapsed)
+	}
+}
+
+// TestPropagateMessage_ObservesCancellation asserts that canceling ctx unblocks propagateMessage even
+// when peers never respond, and that doing so doesn't leave their goroutines running. The peer is added
+// under the same ctx the round runs with, as Start wires eventLoop and the pq workers to one context:
+// a peer's in-flight send is bound by its own context, not by the round's.
+func TestPropagateMessage_ObservesCancellation(t *testing.T) {
+	blockedPeer := p2pcrypto.NewRandomPubkey()
+	ctx, cancel := context.WithCancel(context.Background())
+	var inFlight sync.WaitGroup
+	inFlight.Add(1)
+
+	net := &fakeNetwork{sendFunc: func(ctx context.Context, peer p2pcrypto.PublicKey, protocol string, payload []byte) error {
+		defer inFlight.Done()
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	prot := newTestProtocol(net, config.SwarmConfig{})
+	prot.addPeer(ctx, blockedPeer)
+
+	done := make(chan struct{})
+	go func() {
+		prot.propagateMessage(ctx, []byte("payload"), [12]byte{}, "proto", nil)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("propagateMessage did not observe ctx cancellation")
+	}
+
+	waitDone := make(chan struct{})
+	go func() { inFlight.Wait(); close(waitDone) }()
+	select {
+	case <-waitDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("peer send goroutine leaked past ctx cancellation")
+	}
+}
@@ -0,0 +1,224 @@
+package gossip
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libonomy/libonomy-light/common/types"
+	"github.com/libonomy/libonomy-light/log"
+	"github.com/libonomy/libonomy-light/p2p/config"
+	"github.com/libonomy/libonomy-light/p2p/p2pcrypto"
+	"github.com/libonomy/libonomy-light/p2p/service"
+)
+
+// announceSuffix and getSuffix turn a protocol's regular name into the topic used for its pull-mode
+// control frames, so the underlying network can keep demultiplexing by protocol string the same way it
+// already does for regular gossip traffic.
+const (
+	announceSuffix = "/announce"
+	getSuffix      = "/get"
+)
+
+const (
+	defaultPullFanout      = 6
+	pullAnnounceCacheSize  = 10000
+	pullPendingTTL         = 30 * time.Second
+	pullAnnounceRetryLimit = 3
+)
+
+// pendingGet is the bookkeeping a node that announced a hash keeps while waiting to be asked for it: the
+// payload itself (so HandleGet can answer without re-deriving it) and a TTL so it's eventually forgotten
+// even if nobody asks.
+type pendingGet struct {
+	protocol string
+	payload  []byte
+	got      chan struct{} // closed the first time a get for this hash arrives
+	gotOnce  sync.Once
+}
+
+// newModeMap exists only so NewProtocol, whose config parameter shadows the config package, can still
+// build a map[string]config.Mode.
+func newModeMap() map[string]config.Mode {
+	return make(map[string]config.Mode)
+}
+
+// getMode returns the dissemination mode for protoName: a per-protocol override set via SetMode takes
+// precedence, then config.SwarmConfig.Mode, falling back to config.ModePush.
+func (prot *Protocol) getMode(protoName string) config.Mode {
+	prot.modesMutex.RLock()
+	m, exist := prot.modes[protoName]
+	prot.modesMutex.RUnlock()
+	if exist {
+		return m
+	}
+	return prot.config.Mode
+}
+
+// SetMode overrides the dissemination mode used for protoName, taking precedence over
+// config.SwarmConfig.Mode. Protocols that need every peer to see every message (e.g. consensus) should
+// stay on config.ModePush; high-volume, loss-tolerant protocols can opt into config.ModePull or
+// config.ModeHybrid to cut redundant bandwidth.
+func (prot *Protocol) SetMode(protoName string, mode config.Mode) {
+	prot.modesMutex.Lock()
+	prot.modes[protoName] = mode
+	prot.modesMutex.Unlock()
+}
+
+func (prot *Protocol) pullFanout() int {
+	if prot.config.PullFanout > 0 {
+		return prot.config.PullFanout
+	}
+	return defaultPullFanout
+}
+
+// broadcastPull disseminates payload by announcing its hash to a sampled subset of peers and only
+// shipping the full payload to whichever of them ask for it via HandleGet, instead of pushing payload to
+// every peer up front. Peers that don't ask within sendTimeout are assumed uninterested or unreachable;
+// broadcastPull resamples fresh peers and retries, up to pullAnnounceRetryLimit rounds. alsoSkip is an
+// optional set of pubkeys to treat as already tried, on top of exclude; propagateMessage's ModeHybrid case
+// uses it to keep the peers it already pushed to out of the pull round.
+func (prot *Protocol) broadcastPull(ctx context.Context, payload []byte, h types.Hash12, nextProt string, exclude p2pcrypto.PublicKey, alsoSkip ...p2pcrypto.PublicKey) {
+	pending := &pendingGet{protocol: nextProt, payload: payload, got: make(chan struct{})}
+	prot.pendingMutex.Lock()
+	prot.pending[h] = pending
+	prot.pendingMutex.Unlock()
+
+	go func() {
+		select {
+		case <-time.After(pullPendingTTL):
+		case <-ctx.Done():
+			return
+		}
+		prot.pendingMutex.Lock()
+		delete(prot.pending, h)
+		prot.pendingMutex.Unlock()
+	}()
+
+	priority := prot.getPriority(nextProt)
+	tried := map[p2pcrypto.PublicKey]struct{}{exclude: {}}
+	for _, k := range alsoSkip {
+		tried[k] = struct{}{}
+	}
+
+	for attempt := 0; attempt < pullAnnounceRetryLimit; attempt++ {
+		candidates := make([]p2pcrypto.PublicKey, 0, prot.pullFanout())
+		for _, k := range prot.sampler.SelectPeers(0) {
+			if _, seen := tried[k]; seen {
+				continue
+			}
+			tried[k] = struct{}{}
+			candidates = append(candidates, k)
+			if len(candidates) >= prot.pullFanout() {
+				break
+			}
+		}
+		batch := prot.resolvePeers(candidates)
+		if len(batch) == 0 {
+			return
+		}
+
+		for _, p := range batch {
+			if err := p.enqueue(nextProt+announceSuffix, h[:], priority, nil); err != nil {
+				prot.With().Warning("could not announce to peer", log.String("protocol", nextProt), log.String("to", p.pubkey.String()), log.Err(err))
+			}
+		}
+
+		select {
+		case <-pending.got:
+			return
+		case <-time.After(prot.sendTimeout()):
+			// nobody asked yet, resample and retry with fresh peers
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// dispatchPullFrame recognizes protocol as an announceSuffix/getSuffix topic emitted by broadcastPull or
+// HandleAnnounce, decodes its hash payload, and hands it to the matching handler. handled reports whether
+// protocol was a pull control frame at all; Relay falls back to the regular processMessage path when it's
+// not.
+func (prot *Protocol) dispatchPullFrame(sender p2pcrypto.PublicKey, protocol string, msg service.Data) (handled bool, err error) {
+	if base, ok := trimSuffix(protocol, announceSuffix); ok {
+		h, err := hash12FromPayload(msg.Bytes())
+		if err != nil {
+			return true, err
+		}
+		prot.HandleAnnounce(sender, base, h)
+		return true, nil
+	}
+	if base, ok := trimSuffix(protocol, getSuffix); ok {
+		h, err := hash12FromPayload(msg.Bytes())
+		if err != nil {
+			return true, err
+		}
+		prot.HandleGet(sender, base, h)
+		return true, nil
+	}
+	return false, nil
+}
+
+// trimSuffix reports whether protocol ends with suffix, returning protocol with suffix removed.
+func trimSuffix(protocol, suffix string) (string, bool) {
+	if !strings.HasSuffix(protocol, suffix) {
+		return "", false
+	}
+	return strings.TrimSuffix(protocol, suffix), true
+}
+
+// hash12FromPayload decodes the fixed-size hash a pull control frame carries as its entire payload.
+func hash12FromPayload(payload []byte) (types.Hash12, error) {
+	var h types.Hash12
+	if len(payload) != len(h) {
+		return h, fmt.Errorf("pull control frame: expected %d-byte hash payload, got %d", len(h), len(payload))
+	}
+	copy(h[:], payload)
+	return h, nil
+}
+
+// HandleAnnounce is the entry point for an incoming pull-mode announce: protocol is the original gossip
+// protocol's name (dispatchPullFrame strips the announceSuffix topic back to it). If this node hasn't
+// already requested h, it asks sender for the full payload via a get.
+func (prot *Protocol) HandleAnnounce(sender p2pcrypto.PublicKey, protocol string, h types.Hash12) {
+	prot.peersMutex.RLock()
+	p, exist := prot.peers[sender]
+	prot.peersMutex.RUnlock()
+	if !exist {
+		return
+	}
+
+	if alreadyRequested := prot.pullAnnounceQ.GetOrInsert(h); alreadyRequested {
+		return
+	}
+
+	if err := p.enqueue(protocol+getSuffix, h[:], prot.getPriority(protocol), nil); err != nil {
+		prot.With().Warning("could not send get to peer", log.String("protocol", protocol), log.String("to", sender.String()), log.Err(err))
+	}
+}
+
+// HandleGet is the entry point for an incoming pull-mode get: protocol is the original gossip protocol's
+// name. If this node has a pending payload for h (i.e. it announced it), it's shipped to requester in full.
+func (prot *Protocol) HandleGet(requester p2pcrypto.PublicKey, protocol string, h types.Hash12) {
+	prot.pendingMutex.Lock()
+	pending, exist := prot.pending[h]
+	prot.pendingMutex.Unlock()
+	if !exist {
+		return
+	}
+
+	prot.peersMutex.RLock()
+	p, exist := prot.peers[requester]
+	prot.peersMutex.RUnlock()
+	if !exist {
+		return
+	}
+
+	pending.gotOnce.Do(func() { close(pending.got) })
+
+	if err := p.enqueue(pending.protocol, pending.payload, prot.getPriority(pending.protocol), nil); err != nil {
+		prot.With().Warning("could not send payload to requesting peer", log.String("protocol", protocol), log.String("to", requester.String()), log.Err(err))
+	}
+}
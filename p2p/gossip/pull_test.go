@@ -0,0 +1,91 @@
+package gossip
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/libonomy/libonomy-light/p2p/config"
+	"github.com/libonomy/libonomy-light/p2p/p2pcrypto"
+	"github.com/libonomy/libonomy-light/p2p/service"
+)
+
+// TestRelay_DispatchesAnnounce asserts that an inbound protocol+announceSuffix frame is routed by Relay
+// to HandleAnnounce rather than falling through to processMessage, and that HandleAnnounce answers with a
+// get back to the announcing peer.
+func TestRelay_DispatchesAnnounce(t *testing.T) {
+	sender := p2pcrypto.NewRandomPubkey()
+	var mu sync.Mutex
+	var gotProtocol string
+	var gotPayload []byte
+	sent := make(chan struct{})
+
+	net := &fakeNetwork{sendFunc: func(ctx context.Context, peer p2pcrypto.PublicKey, protocol string, payload []byte) error {
+		mu.Lock()
+		gotProtocol, gotPayload = protocol, payload
+		mu.Unlock()
+		close(sent)
+		return nil
+	}}
+	prot := newTestProtocol(net, config.SwarmConfig{})
+	prot.addPeer(prot.ctx, sender)
+
+	h := testHash12()
+	if err := prot.Relay(sender, "blocks"+announceSuffix, service.DataBytes{Payload: h[:]}); err != nil {
+		t.Fatalf("Relay returned error: %v", err)
+	}
+
+	<-sent
+	mu.Lock()
+	defer mu.Unlock()
+	if gotProtocol != "blocks"+getSuffix {
+		t.Fatalf("expected a get on %q, got %q", "blocks"+getSuffix, gotProtocol)
+	}
+	if string(gotPayload) != string(h[:]) {
+		t.Fatalf("expected get payload %x, got %x", h[:], gotPayload)
+	}
+}
+
+// TestRelay_DispatchesGet asserts that an inbound protocol+getSuffix frame is routed to HandleGet, which
+// ships the pending payload back to the requester.
+func TestRelay_DispatchesGet(t *testing.T) {
+	requester := p2pcrypto.NewRandomPubkey()
+	var mu sync.Mutex
+	var gotProtocol string
+	var gotPayload []byte
+	sent := make(chan struct{})
+
+	net := &fakeNetwork{sendFunc: func(ctx context.Context, peer p2pcrypto.PublicKey, protocol string, payload []byte) error {
+		mu.Lock()
+		gotProtocol, gotPayload = protocol, payload
+		mu.Unlock()
+		close(sent)
+		return nil
+	}}
+	prot := newTestProtocol(net, config.SwarmConfig{})
+	prot.addPeer(prot.ctx, requester)
+
+	h := testHash12()
+	prot.pendingMutex.Lock()
+	prot.pending[h] = &pendingGet{protocol: "blocks", payload: []byte("the payload"), got: make(chan struct{})}
+	prot.pendingMutex.Unlock()
+
+	if err := prot.Relay(requester, "blocks"+getSuffix, service.DataBytes{Payload: h[:]}); err != nil {
+		t.Fatalf("Relay returned error: %v", err)
+	}
+
+	<-sent
+	mu.Lock()
+	defer mu.Unlock()
+	if gotProtocol != "blocks" {
+		t.Fatalf("expected payload delivered on %q, got %q", "blocks", gotProtocol)
+	}
+	if string(gotPayload) != "the payload" {
+		t.Fatalf("expected pending payload to be delivered, got %q", gotPayload)
+	}
+}
+
+func testHash12() (h [12]byte) {
+	copy(h[:], "abcdefghijkl")
+	return h
+}
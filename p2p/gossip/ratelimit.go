@@ -0,0 +1,101 @@
+package gossip
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/libonomy/libonomy-light/log"
+)
+
+const defaultPQWorkers = 4
+
+// rateLimitRetryInterval is how long requeueAfterRateLimit waits before giving a throttled protocol's
+// bucket another chance to admit its message.
+const rateLimitRetryInterval = 20 * time.Millisecond
+
+// tokenBucket is a simple token-bucket rate limiter: tokens refill continuously at rate per second, up to
+// burst, and Allow consumes one if available.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(msgsPerSec float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   msgsPerSec,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Allow reports whether a message may be sent now, consuming a token if so.
+func (tb *tokenBucket) Allow() bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	now := time.Now()
+	tb.tokens += now.Sub(tb.last).Seconds() * tb.rate
+	if tb.tokens > tb.burst {
+		tb.tokens = tb.burst
+	}
+	tb.last = now
+
+	if tb.tokens < 1 {
+		return false
+	}
+	tb.tokens--
+	return true
+}
+
+// SetRateLimit caps protoName's propagation rate to msgsPerSec messages per second, allowing bursts up to
+// burst. A misbehaving high-volume protocol is throttled here rather than being allowed to starve other
+// protocols' messages out of the priority queue workers. Protocols without an explicit rate limit are
+// unthrottled.
+func (prot *Protocol) SetRateLimit(protoName string, msgsPerSec float64, burst int) {
+	prot.rateLimitMutex.Lock()
+	prot.rateLimiters[protoName] = newTokenBucket(msgsPerSec, burst)
+	prot.rateLimitMutex.Unlock()
+}
+
+// allowPropagation reports whether protoName may propagate a message right now, consulting its
+// rate limiter if one was set via SetRateLimit. Protocols with no limiter configured are always allowed.
+func (prot *Protocol) allowPropagation(protoName string) bool {
+	prot.rateLimitMutex.RLock()
+	limiter, exist := prot.rateLimiters[protoName]
+	prot.rateLimitMutex.RUnlock()
+	if !exist {
+		return true
+	}
+	return limiter.Allow()
+}
+
+// requeueAfterRateLimit writes item back onto prot.pq after rateLimitRetryInterval, without blocking the
+// calling handlePQ worker: the worker is free to immediately pick up the next queued message (possibly
+// for an unrelated, unthrottled protocol) instead of sitting idle on a bucket that hasn't refilled yet.
+// If item's protocol is still over its limit when it's dequeued again, it goes through this same path
+// again. The wait is run in its own short-lived goroutine, one per in-flight deferred message, the same
+// pattern propagateMessage already uses to let stragglers finish without blocking their caller.
+func (prot *Protocol) requeueAfterRateLimit(ctx context.Context, item pqItem) {
+	go func() {
+		select {
+		case <-time.After(rateLimitRetryInterval):
+		case <-ctx.Done():
+			return
+		}
+		if err := prot.pq.Write(prot.getPriority(item.msg.Protocol()), item); err != nil {
+			prot.With().Error("fatal: could not requeue rate-limited message", log.Err(err), log.String("protocol", item.msg.Protocol()))
+		}
+	}()
+}
+
+func (prot *Protocol) pqWorkers() int {
+	if prot.config.PQWorkers > 0 {
+		return prot.config.PQWorkers
+	}
+	return defaultPQWorkers
+}
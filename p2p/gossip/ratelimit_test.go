@@ -0,0 +1,140 @@
+package gossip
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libonomy/libonomy-light/p2p/config"
+	"github.com/libonomy/libonomy-light/p2p/p2pcrypto"
+)
+
+// fakeValidation is a minimal service.MessageValidation used to build pqItems for requeueAfterRateLimit
+// tests without needing a real network layer.
+type fakeValidation struct {
+	protocol string
+	message  []byte
+	sender   p2pcrypto.PublicKey
+}
+
+func (f fakeValidation) Protocol() string            { return f.protocol }
+func (f fakeValidation) Message() []byte             { return f.message }
+func (f fakeValidation) Sender() p2pcrypto.PublicKey { return f.sender }
+
+// TestRequeueAfterRateLimit_RequeuesRatherThanDrops is the regression test for the reported silent drop:
+// a rate-limited message must reappear on prot.pq, not vanish.
+func TestRequeueAfterRateLimit_RequeuesRatherThanDrops(t *testing.T) {
+	prot := newTestProtocol(&fakeNetwork{}, config.SwarmConfig{})
+	defer prot.pq.Close()
+
+	item := pqItem{msg: fakeValidation{protocol: "noisy", message: []byte("m")}, enqueuedAt: time.Now()}
+	prot.requeueAfterRateLimit(context.Background(), item)
+
+	read := make(chan interface{}, 1)
+	go func() {
+		if qi, err := prot.pq.Read(); err == nil {
+			read <- qi
+		}
+	}()
+
+	select {
+	case qi := <-read:
+		got, ok := qi.(pqItem)
+		if !ok || got.msg.Protocol() != "noisy" {
+			t.Fatalf("unexpected requeued item: %#v", qi)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("rate-limited message was never requeued; it appears to have been dropped")
+	}
+}
+
+// TestRequeueAfterRateLimit_ObservesCancellation asserts a shutdown skips the requeue instead of writing
+// to a priority queue that's about to be (or already was) closed.
+func TestRequeueAfterRateLimit_ObservesCancellation(t *testing.T) {
+	prot := newTestProtocol(&fakeNetwork{}, config.SwarmConfig{})
+	defer prot.pq.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	item := pqItem{msg: fakeValidation{protocol: "noisy", message: []byte("m")}, enqueuedAt: time.Now()}
+	prot.requeueAfterRateLimit(ctx, item)
+
+	read := make(chan interface{}, 1)
+	go func() {
+		if qi, err := prot.pq.Read(); err == nil {
+			read <- qi
+		}
+	}()
+
+	select {
+	case qi := <-read:
+		t.Fatalf("expected a canceled requeue not to write the message back, got %#v", qi)
+	case <-time.After(rateLimitRetryInterval * 5):
+	}
+}
+
+// TestAllowPropagation_ThrottlingIsPerProtocol asserts that exhausting one protocol's rate limit has no
+// effect on another's: a throttled protocol must not be able to starve unrelated protocols out of the
+// shared pq workers.
+func TestAllowPropagation_ThrottlingIsPerProtocol(t *testing.T) {
+	prot := newTestProtocol(&fakeNetwork{}, config.SwarmConfig{})
+	prot.SetRateLimit("noisy", 0, 0)
+
+	if prot.allowPropagation("noisy") {
+		t.Fatal("expected noisy to be throttled")
+	}
+	if !prot.allowPropagation("quiet") {
+		t.Fatal("throttling one protocol must not affect an unrelated, unthrottled protocol")
+	}
+}
+
+// TestPropagationEventLoop_FastProtocolNotStarvedBySlow drives propagationEventLoop/handlePQ end to end
+// with PQWorkers > 1 and asserts a fast protocol's message is still processed by a free worker while
+// another worker is stuck inside propagateMessage for a slow protocol, instead of queuing behind it.
+// Each message excludes the other protocol's peer (mirroring the "don't echo back to the sender" use of
+// exclude), so the slow round's in-flight send can only ever block the worker handling it, not the peer
+// the fast round delivers to.
+func TestPropagationEventLoop_FastProtocolNotStarvedBySlow(t *testing.T) {
+	slowPeerKey := p2pcrypto.NewRandomPubkey()
+	fastPeerKey := p2pcrypto.NewRandomPubkey()
+
+	slowRelease := make(chan struct{})
+	defer close(slowRelease)
+	fastDelivered := make(chan struct{}, 1)
+
+	net := &fakeNetwork{sendFunc: func(ctx context.Context, peer p2pcrypto.PublicKey, protocol string, payload []byte) error {
+		switch protocol {
+		case "slow":
+			select {
+			case <-slowRelease:
+			case <-ctx.Done():
+			}
+		case "fast":
+			select {
+			case fastDelivered <- struct{}{}:
+			default:
+			}
+		}
+		return nil
+	}}
+
+	prot := newTestProtocol(net, config.SwarmConfig{PQWorkers: 2})
+	defer prot.Close()
+	prot.addPeer(prot.ctx, slowPeerKey)
+	prot.addPeer(prot.ctx, fastPeerKey)
+
+	go prot.propagationEventLoop(prot.ctx)
+
+	prot.propagateQ <- fakeValidation{protocol: "slow", message: []byte("slow-payload"), sender: fastPeerKey}
+	// give a worker a moment to pick up the slow message and block inside propagateMessage before the fast
+	// message is queued behind it.
+	time.Sleep(20 * time.Millisecond)
+	prot.propagateQ <- fakeValidation{protocol: "fast", message: []byte("fast-payload"), sender: slowPeerKey}
+
+	select {
+	case <-fastDelivered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("fast protocol's message was starved behind the slow protocol's in-flight propagation; with PQWorkers > 1 a free worker should have processed it")
+	}
+}
@@ -0,0 +1,41 @@
+package gossip
+
+import (
+	"math/rand"
+
+	"github.com/libonomy/libonomy-light/p2p/p2pcrypto"
+)
+
+// PeerSampler selects peers to use for a single round of gossip dissemination, whether that's the push
+// fanout in propagateMessage or the announce fanout in broadcastPull. Keeping both behind one abstraction
+// means they can share a sampling strategy (or diverge, by swapping in a different PeerSampler via
+// Protocol.SetPeerSampler) instead of each growing its own ad-hoc peer selection. SelectPeers deals in
+// p2pcrypto.PublicKey rather than Protocol's unexported peer type, so a PeerSampler can be implemented
+// entirely outside this package.
+type PeerSampler interface {
+	// SelectPeers returns up to qty peer public keys, in no particular order. A qty <= 0 or >= the number
+	// of known peers returns every known peer, shuffled.
+	SelectPeers(qty int) []p2pcrypto.PublicKey
+}
+
+// uniformSampler is the default PeerSampler: it shuffles Protocol's current peer set and returns the
+// first qty of them.
+type uniformSampler struct {
+	prot *Protocol
+}
+
+func (s uniformSampler) SelectPeers(qty int) []p2pcrypto.PublicKey {
+	s.prot.peersMutex.RLock()
+	keys := make([]p2pcrypto.PublicKey, 0, len(s.prot.peers))
+	for k := range s.prot.peers {
+		keys = append(keys, k)
+	}
+	s.prot.peersMutex.RUnlock()
+
+	rand.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+
+	if qty > 0 && qty < len(keys) {
+		keys = keys[:qty]
+	}
+	return keys
+}
@@ -0,0 +1,164 @@
+// Package metrics holds the prometheus collectors used across the p2p layer.
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// ProtocolLabel is the label used to break down gossip metrics by protocol name.
+const ProtocolLabel = "protocol"
+
+// PeerLabel is the label used to break down per-peer gossip metrics.
+const PeerLabel = "peer"
+
+// counterVec wraps a prometheus.CounterVec so callers can do metrics.X.With(label, value).Add(n)
+// instead of building a prometheus.Labels map by hand.
+type counterVec struct{ *prometheus.CounterVec }
+
+func (c counterVec) With(labelKey, labelValue string) prometheus.Counter {
+	return c.CounterVec.With(prometheus.Labels{labelKey: labelValue})
+}
+
+// DeleteLabelValues removes the series for labelValue, e.g. once the peer or protocol it tracked is gone.
+func (c counterVec) DeleteLabelValues(labelValue string) bool {
+	return c.CounterVec.DeleteLabelValues(labelValue)
+}
+
+// gaugeVec wraps a prometheus.GaugeVec the same way, for metrics.X.With(label, value).Set(n).
+type gaugeVec struct{ *prometheus.GaugeVec }
+
+func (g gaugeVec) With(labelKey, labelValue string) prometheus.Gauge {
+	return g.GaugeVec.With(prometheus.Labels{labelKey: labelValue})
+}
+
+// DeleteLabelValues removes the series for labelValue, e.g. once the peer or protocol it tracked is gone.
+func (g gaugeVec) DeleteLabelValues(labelValue string) bool {
+	return g.GaugeVec.DeleteLabelValues(labelValue)
+}
+
+var (
+	// OldGossipMessages counts gossip messages that were already seen and therefore dropped.
+	OldGossipMessages = counterVec{prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "old_gossip_messages",
+			Help: "number of gossip messages identified as already processed",
+		},
+		[]string{ProtocolLabel},
+	)}
+
+	// NewGossipMessages counts gossip messages that were new and passed on for validation.
+	NewGossipMessages = counterVec{prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "new_gossip_messages",
+			Help: "number of new gossip messages processed",
+		},
+		[]string{ProtocolLabel},
+	)}
+
+	// PropagationQueueLen tracks the length of the propagation queue feeding the priority queue.
+	PropagationQueueLen = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "propagation_queue_length",
+			Help: "number of messages waiting to be written to the gossip priority queue",
+		},
+	)
+
+	// BroadcastPeersAttempted counts peers a broadcast round attempted to send to, by protocol.
+	BroadcastPeersAttempted = counterVec{prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "broadcast_peers_attempted",
+			Help: "number of peers a gossip broadcast round attempted to deliver to",
+		},
+		[]string{ProtocolLabel},
+	)}
+
+	// BroadcastPeersDelivered counts peers a broadcast round successfully delivered to, by protocol.
+	BroadcastPeersDelivered = counterVec{prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "broadcast_peers_delivered",
+			Help: "number of peers a gossip broadcast round successfully delivered to",
+		},
+		[]string{ProtocolLabel},
+	)}
+
+	// BroadcastRoundLatency tracks how long a broadcast round took to complete, by protocol: until it reached
+	// its fanout target, every send finished, or its ctx was canceled, whichever came first.
+	BroadcastRoundLatency = gaugeVec{prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "broadcast_round_latency_seconds",
+			Help: "seconds elapsed until a gossip broadcast round completed (delivery-ratio target reached, all sends finished, or canceled)",
+		},
+		[]string{ProtocolLabel},
+	)}
+
+	// PeerQueueLength tracks the current depth of a peer's outbound send queue.
+	PeerQueueLength = gaugeVec{prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "peer_queue_length",
+			Help: "current number of messages queued for a peer's writer goroutine",
+		},
+		[]string{PeerLabel},
+	)}
+
+	// PeerQueueDrops counts messages dropped for a peer because its outbound queue was full.
+	PeerQueueDrops = counterVec{prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "peer_queue_drops",
+			Help: "number of messages dropped because a peer's outbound queue was full",
+		},
+		[]string{PeerLabel},
+	)}
+
+	// PeerSendErrors counts SendMessage failures per peer.
+	PeerSendErrors = counterVec{prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "peer_send_errors",
+			Help: "number of SendMessage failures for a peer",
+		},
+		[]string{PeerLabel},
+	)}
+
+	// PropagationThroughput counts messages a priority-queue worker propagated, by protocol.
+	PropagationThroughput = counterVec{prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "propagation_throughput",
+			Help: "number of messages propagated from the gossip priority queue",
+		},
+		[]string{ProtocolLabel},
+	)}
+
+	// PropagationQueueWait tracks how long a message waited in the priority queue before a worker picked
+	// it up, by protocol.
+	PropagationQueueWait = gaugeVec{prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "propagation_queue_wait_seconds",
+			Help: "seconds a message waited in the gossip priority queue before being propagated",
+		},
+		[]string{ProtocolLabel},
+	)}
+
+	// RateLimitDeferrals counts messages requeued because a protocol's rate limit was exceeded; they are
+	// delayed, not lost, so this tracks backpressure rather than message loss.
+	RateLimitDeferrals = counterVec{prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rate_limit_deferrals",
+			Help: "number of messages requeued because a protocol's rate limit was exceeded",
+		},
+		[]string{ProtocolLabel},
+	)}
+)
+
+func init() {
+	prometheus.MustRegister(
+		OldGossipMessages.CounterVec,
+		NewGossipMessages.CounterVec,
+		PropagationQueueLen,
+		BroadcastPeersAttempted.CounterVec,
+		BroadcastPeersDelivered.CounterVec,
+		BroadcastRoundLatency.GaugeVec,
+		PeerQueueLength.GaugeVec,
+		PeerQueueDrops.CounterVec,
+		PeerSendErrors.CounterVec,
+		PropagationThroughput.CounterVec,
+		PropagationQueueWait.GaugeVec,
+		RateLimitDeferrals.CounterVec,
+	)
+}